@@ -0,0 +1,68 @@
+package transmission
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// AltSpeedDays is a bitmask of weekdays the alt-speed schedule applies to,
+// matching Transmission's "alt-speed-time-day" field.
+type AltSpeedDays int
+
+// Individual weekdays, combinable to build a schedule.
+const (
+	AltSpeedSunday AltSpeedDays = 1 << iota
+	AltSpeedMonday
+	AltSpeedTuesday
+	AltSpeedWednesday
+	AltSpeedThursday
+	AltSpeedFriday
+	AltSpeedSaturday
+)
+
+// Common combinations.
+const (
+	AltSpeedWeekdays = AltSpeedMonday | AltSpeedTuesday | AltSpeedWednesday | AltSpeedThursday | AltSpeedFriday
+	AltSpeedWeekend  = AltSpeedSaturday | AltSpeedSunday
+	AltSpeedEveryDay = AltSpeedWeekdays | AltSpeedWeekend
+)
+
+// SetAltSpeedSchedule configures and enables the alt-speed time schedule:
+// begin and end are minutes since midnight, as used by the Transmission
+// RPC spec, and days selects which weekdays it applies on.
+func (s *Session) SetAltSpeedSchedule(begin, end int, days AltSpeedDays) error {
+	enabled := true
+	daysInt := int(days)
+	return s.Client.SetSession(SessionSetArg{
+		AltSpeedTimeBegin:   &begin,
+		AltSpeedTimeEnd:     &end,
+		AltSpeedTimeDay:     &daysInt,
+		AltSpeedTimeEnabled: &enabled,
+	})
+}
+
+// WithAltSpeed toggles alt-speed-enabled on, runs fn, then restores it to
+// whatever it was before the call, regardless of whether fn succeeded. It's
+// a convenience for throttling a single operation (e.g. a bulk backup)
+// without requiring callers to track and restore the session's prior
+// alt-speed state themselves.
+func (s *Session) WithAltSpeed(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, err := s.Client.GetSession(); err != nil {
+		return fmt.Errorf("failed to fetch session: %w", err)
+	}
+	prior := s.AltSpeedEnabled
+
+	enabled := true
+	if err := s.Client.SetSession(SessionSetArg{AltSpeedEnabled: &enabled}); err != nil {
+		return fmt.Errorf("failed to enable alt speed: %w", err)
+	}
+
+	err := fn(ctx)
+
+	if restoreErr := s.Client.SetSession(SessionSetArg{AltSpeedEnabled: &prior}); restoreErr != nil {
+		return errors.Join(err, fmt.Errorf("failed to restore alt speed: %w", restoreErr))
+	}
+
+	return err
+}
@@ -0,0 +1,63 @@
+package transmission
+
+import "fmt"
+
+// TorrentBackend is the set of operations a torrent daemon must support to
+// be usable through this package. Client implements it against the native
+// Transmission RPC API; QBittorrentClient implements it against the
+// qBittorrent v2 Web API. Callers that only need this common surface should
+// depend on TorrentBackend instead of *Client so either daemon can be
+// selected at startup via New or NewQBittorrent.
+type TorrentBackend interface {
+	AddTorrent(args AddTorrentArg) (*Torrent, error)
+	GetTorrents(fields []string) ([]*Torrent, error)
+	RemoveTorrents(torrents []*Torrent, removeData bool) error
+
+	QueueMoveTop(torrents []*Torrent) error
+	QueueMoveUp(torrents []*Torrent) error
+	QueueMoveDown(torrents []*Torrent) error
+	QueueMoveBottom(torrents []*Torrent) error
+
+	FreeSpace(path string) (int, error)
+	PortTest() (bool, error)
+	BlocklistUpdate() (int, error)
+
+	GetSession() (*Session, error)
+	SetSession(args SessionSetArg) error
+}
+
+var _ TorrentBackend = (*Client)(nil)
+
+// SessionSetArg params for Client.SetSession, see the "session-set" section
+// of the Transmission RPC spec for the full list of accepted fields. Only
+// the fields set here are sent; zero values are omitted.
+type SessionSetArg struct {
+	AltSpeedDown        int   `json:"alt-speed-down,omitempty"`
+	AltSpeedUp          int   `json:"alt-speed-up,omitempty"`
+	AltSpeedEnabled     *bool `json:"alt-speed-enabled,omitempty"`
+	AltSpeedTimeEnabled *bool `json:"alt-speed-time-enabled,omitempty"`
+	// AltSpeedTimeBegin/End/Day are pointers, not plain ints, because 0 is
+	// a meaningful value for each of them (midnight, and "no days").
+	AltSpeedTimeBegin *int `json:"alt-speed-time-begin,omitempty"`
+	AltSpeedTimeEnd   *int `json:"alt-speed-time-end,omitempty"`
+	AltSpeedTimeDay   *int `json:"alt-speed-time-day,omitempty"`
+}
+
+// GetSession fetches the current session arguments from the server and
+// returns the updated Session.
+func (c *Client) GetSession() (*Session, error) {
+	if err := c.Session.Update(); err != nil {
+		return nil, fmt.Errorf("failed to fetch session: %w", err)
+	}
+	return c.Session, nil
+}
+
+// SetSession applies session arguments via the "session-set" RPC method.
+func (c *Client) SetSession(args SessionSetArg) error {
+	tReq := &Request{
+		Arguments: args,
+		Method:    "session-set",
+	}
+	r := &Response{}
+	return c.request(tReq, r)
+}
@@ -0,0 +1,146 @@
+package transmission
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// bencodeDecode reads a single bencoded value from r. It is deliberately
+// minimal: just enough to load a .torrent metainfo dict, tweak a field, and
+// re-encode it with bencodeEncode. Strings decode to []byte, integers to
+// int64, lists to []any and dicts to map[string]any.
+func bencodeDecode(r *bufio.Reader) (any, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case b == 'i':
+		return bencodeDecodeInt(r)
+	case b == 'l':
+		return bencodeDecodeList(r)
+	case b == 'd':
+		return bencodeDecodeDict(r)
+	case b >= '0' && b <= '9':
+		if err := r.UnreadByte(); err != nil {
+			return nil, err
+		}
+		return bencodeDecodeString(r)
+	default:
+		return nil, fmt.Errorf("bencode: unexpected token %q", b)
+	}
+}
+
+func bencodeDecodeInt(r *bufio.Reader) (int64, error) {
+	s, err := r.ReadString('e')
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(s[:len(s)-1], 10, 64)
+}
+
+func bencodeDecodeString(r *bufio.Reader) ([]byte, error) {
+	lenStr, err := r.ReadString(':')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(lenStr[:len(lenStr)-1])
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+func bencodeDecodeList(r *bufio.Reader) ([]any, error) {
+	var list []any
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if b == 'e' {
+			return list, nil
+		}
+		if err := r.UnreadByte(); err != nil {
+			return nil, err
+		}
+		v, err := bencodeDecode(r)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+	}
+}
+
+func bencodeDecodeDict(r *bufio.Reader) (map[string]any, error) {
+	dict := make(map[string]any)
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if b == 'e' {
+			return dict, nil
+		}
+		if err := r.UnreadByte(); err != nil {
+			return nil, err
+		}
+		key, err := bencodeDecodeString(r)
+		if err != nil {
+			return nil, err
+		}
+		val, err := bencodeDecode(r)
+		if err != nil {
+			return nil, err
+		}
+		dict[string(key)] = val
+	}
+}
+
+// bencodeEncode writes v, following the same type mapping as
+// bencodeDecode, in canonical form (dict keys sorted lexically).
+func bencodeEncode(buf *bytes.Buffer, v any) error {
+	switch val := v.(type) {
+	case int64:
+		fmt.Fprintf(buf, "i%de", val)
+	case []byte:
+		fmt.Fprintf(buf, "%d:", len(val))
+		buf.Write(val)
+	case string:
+		fmt.Fprintf(buf, "%d:%s", len(val), val)
+	case []any:
+		buf.WriteByte('l')
+		for _, item := range val {
+			if err := bencodeEncode(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('e')
+	case map[string]any:
+		buf.WriteByte('d')
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(buf, "%d:%s", len(k), k)
+			if err := bencodeEncode(buf, val[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('e')
+	default:
+		return fmt.Errorf("bencode: unsupported type %T", v)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package transmission
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+)
+
+func TestBencodeRoundTrip(t *testing.T) {
+	cases := []any{
+		int64(42),
+		int64(0),
+		[]byte("hello"),
+		[]any{int64(1), []byte("two")},
+		map[string]any{"a": int64(1), "b": []byte("two")},
+		map[string]any{
+			"info":     map[string]any{"name": []byte("x"), "piece length": int64(16384)},
+			"url-list": []any{[]byte("http://a"), []byte("http://b")},
+		},
+	}
+
+	for _, c := range cases {
+		var buf bytes.Buffer
+		if err := bencodeEncode(&buf, c); err != nil {
+			t.Fatalf("encode %#v: %v", c, err)
+		}
+
+		got, err := bencodeDecode(bufio.NewReader(bytes.NewReader(buf.Bytes())))
+		if err != nil {
+			t.Fatalf("decode %#v: %v", c, err)
+		}
+
+		// Re-encoding the decoded value and comparing bytes sidesteps having
+		// to compare []byte vs string vs map key order by hand; canonical
+		// bencode is deterministic, so equal values produce equal bytes.
+		var redone bytes.Buffer
+		if err := bencodeEncode(&redone, got); err != nil {
+			t.Fatalf("re-encode %#v: %v", got, err)
+		}
+		if redone.String() != buf.String() {
+			t.Errorf("round trip mismatch for %#v: got %q, want %q", c, redone.String(), buf.String())
+		}
+	}
+}
+
+func TestBencodeEncodeDictKeysSorted(t *testing.T) {
+	var buf bytes.Buffer
+	if err := bencodeEncode(&buf, map[string]any{
+		"z": int64(1),
+		"a": int64(2),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "d1:ai2e1:zi1ee"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
@@ -8,11 +8,32 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 )
 
 // ErrDuplicateTorrent returned when the torrent is already added
 var ErrDuplicateTorrent = errors.New("Torrent already added")
 
+// DuplicateTorrentError is returned by AddTorrent instead of the bare
+// ErrDuplicateTorrent when Transmission reports "torrent-duplicate". It
+// carries the existing torrent handle so callers can decide whether to
+// re-use it, re-announce it, or relocate it, without a follow-up
+// GetTorrents scan. It satisfies errors.Is(err, ErrDuplicateTorrent).
+type DuplicateTorrentError struct {
+	// Torrent is the torrent already known to Transmission.
+	Torrent *Torrent
+}
+
+func (e *DuplicateTorrentError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrDuplicateTorrent, e.Torrent.Name)
+}
+
+// Is allows errors.Is(err, ErrDuplicateTorrent) to succeed for a
+// *DuplicateTorrentError.
+func (e *DuplicateTorrentError) Is(target error) bool {
+	return target == ErrDuplicateTorrent
+}
+
 // Config used to configure transmission client
 type Config struct {
 	Address  string
@@ -28,6 +49,9 @@ type Client struct {
 	sessionID string
 	Context   context.Context
 	*Config
+
+	eventsMu sync.Mutex
+	events   *eventLoop
 }
 
 // AddTorrentArg params for Client.AddTorrent
@@ -54,6 +78,13 @@ type AddTorrentArg struct {
 	PriorityHigh      []int `json:"priority-high,omitempty"`
 	PriorityLow       []int `json:"priority-low,omitempty"`
 	PriorityNormal    []int `json:"priority-normal,omitempty"`
+	// Webseeds are HTTP/FTP seed URLs (BEP 19) merged into the metainfo's
+	// "url-list" before it is sent. Requires Metainfo: AddTorrent returns an
+	// error if Webseeds is set on a Filename-only add, since Transmission has
+	// no way to attach webseeds to one.
+	Webseeds []string `json:"-"`
+	// Group assigns the torrent to a bandwidth group, see Client.SetGroup.
+	Group string `json:"group,omitempty"`
 }
 
 // Request object for API call
@@ -229,33 +260,56 @@ func (c *Client) Add(filename string) (*Torrent, error) {
 // AddTorrent add torrent from filename or metadata see AddTorrentArg for
 // arguments
 func (c *Client) AddTorrent(args AddTorrentArg) (*Torrent, error) {
+	if len(args.Webseeds) > 0 {
+		if args.Metainfo == "" {
+			return nil, fmt.Errorf("webseeds require Metainfo, Filename-only adds can't carry a url-list")
+		}
+		metainfo, err := injectWebseeds(args.Metainfo, args.Webseeds)
+		if err != nil {
+			return nil, fmt.Errorf("failed to inject webseeds: %w", err)
+		}
+		args.Metainfo = metainfo
+	}
+
 	tReq := &Request{
 		Arguments: args,
 		Method:    "torrent-add",
 	}
-	// TODO: When there is an error like that, the name of the error is in the
-	// name of the key of the JSON
-	// Here we only get the torrent-added response, but in other cases the
-	// response could be for example torrent-duplicate
-	// Need to unmarshal with json.RawMessage
-	type added struct {
-		Torrent *Torrent `json:"torrent-added"`
-	}
-	r := &Response{Arguments: &added{}}
+
+	// The "arguments" object has exactly one key, either "torrent-added" or
+	// "torrent-duplicate", so we unmarshal it generically first and inspect
+	// which one is present.
+	r := &Response{Arguments: &json.RawMessage{}}
 	err := c.request(tReq, r)
 	if err != nil {
 		return nil, err
 	}
-	t := r.Arguments.(*added)
 
-	// If it's a success but we didn't add any torrent, it's because the
-	// torrent is already added
-	if t.Torrent == nil {
-		return nil, ErrDuplicateTorrent
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(*r.Arguments.(*json.RawMessage), &keys); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal torrent-add response: %w", err)
+	}
+
+	if raw, ok := keys["torrent-duplicate"]; ok {
+		t := &Torrent{}
+		if err := json.Unmarshal(raw, t); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal duplicate torrent: %w", err)
+		}
+		t.Client = c
+		return nil, &DuplicateTorrentError{Torrent: t}
+	}
+
+	raw, ok := keys["torrent-added"]
+	if !ok {
+		return nil, fmt.Errorf("transmission: torrent-add response had neither torrent-added nor torrent-duplicate")
 	}
 
-	t.Torrent.Client = c
-	return t.Torrent, nil
+	t := &Torrent{}
+	if err := json.Unmarshal(raw, t); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal added torrent: %w", err)
+	}
+	t.Client = c
+	return t, nil
 }
 
 // RemoveTorrents remove torrents
@@ -0,0 +1,80 @@
+package transmission
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	return &Client{
+		Context: context.Background(),
+		Config:  &Config{Address: srv.URL, HTTPClient: srv.Client()},
+	}
+}
+
+func TestAddTorrentAdded(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"arguments":{"torrent-added":{"id":1,"hashString":"cafe","name":"new"}},"result":"success"}`)
+	})
+
+	torrent, err := c.AddTorrent(AddTorrentArg{Filename: "magnet:?xt=urn:btih:cafe"})
+	if err != nil {
+		t.Fatalf("AddTorrent: %v", err)
+	}
+	if torrent.HashString != "cafe" || torrent.ID != 1 || torrent.Name != "new" {
+		t.Errorf("unexpected torrent: %+v", torrent)
+	}
+	if torrent.Client != c {
+		t.Errorf("torrent.Client not set to the calling Client")
+	}
+}
+
+func TestAddTorrentDuplicate(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"arguments":{"torrent-duplicate":{"id":7,"hashString":"deadbeef","name":"existing"}},"result":"success"}`)
+	})
+
+	torrent, err := c.AddTorrent(AddTorrentArg{Filename: "magnet:?xt=urn:btih:deadbeef"})
+	if torrent != nil {
+		t.Errorf("want nil torrent on duplicate, got %+v", torrent)
+	}
+	if err == nil {
+		t.Fatal("want an error, got nil")
+	}
+	if !errors.Is(err, ErrDuplicateTorrent) {
+		t.Errorf("errors.Is(err, ErrDuplicateTorrent) = false, err: %v", err)
+	}
+
+	var dup *DuplicateTorrentError
+	if !errors.As(err, &dup) {
+		t.Fatalf("errors.As(err, *DuplicateTorrentError) failed, err: %v", err)
+	}
+	if dup.Torrent.HashString != "deadbeef" || dup.Torrent.ID != 7 {
+		t.Errorf("unexpected duplicate torrent: %+v", dup.Torrent)
+	}
+}
+
+func TestAddTorrentUnexpectedResponse(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"arguments":{},"result":"success"}`)
+	})
+
+	torrent, err := c.AddTorrent(AddTorrentArg{Filename: "magnet:?xt=urn:btih:cafe"})
+	if torrent != nil {
+		t.Errorf("want nil torrent on unexpected response, got %+v", torrent)
+	}
+	if err == nil {
+		t.Fatal("want an error, got nil")
+	}
+	if errors.Is(err, ErrDuplicateTorrent) {
+		t.Errorf("an unexpected response should not be reported as a duplicate, err: %v", err)
+	}
+}
@@ -0,0 +1,256 @@
+package transmission
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// TorrentEventType identifies the kind of change a TorrentEvent reports.
+type TorrentEventType int
+
+const (
+	// TorrentAdded fires the first time a hash is seen in a poll.
+	TorrentAdded TorrentEventType = iota
+	// TorrentRemoved fires once a previously seen hash disappears.
+	TorrentRemoved
+	// StateChanged fires when a torrent's Status changes.
+	StateChanged
+	// Completed fires the moment a torrent's IsFinished flips to true.
+	Completed
+	// ErrorSet fires when a torrent's ErrorString changes to a non-empty
+	// value.
+	ErrorSet
+	// ProgressTick fires on every poll for every still-present torrent,
+	// carrying the latest progress and rate figures.
+	ProgressTick
+	// TrackerStatusChanged fires when a torrent's Error code enters or
+	// leaves the tracker-warning/tracker-error range (1 or 2).
+	TrackerStatusChanged
+	// EventError fires when a poll itself fails; the loop keeps running
+	// with exponential backoff.
+	EventError
+)
+
+// TorrentEvent is emitted on the channel returned by Client.Subscribe.
+type TorrentEvent struct {
+	Type        TorrentEventType
+	Torrent     *Torrent
+	From        Status
+	To          Status
+	ErrorString string
+	PercentDone float64
+	RateDown    int
+	RateUp      int
+	Err         error
+}
+
+// Subscribe returns a channel of torrent events, computed by polling
+// torrent-get every interval and diffing against the previous snapshot.
+// Fields follows the same rules as GetTorrents. Multiple calls on the same
+// Client share one poll loop; the channel closes once ctx is done, and
+// closing it does not affect other subscribers. On a failed poll the loop
+// emits an EventError and keeps going with exponential backoff, up to a
+// one minute ceiling.
+//
+// Since the loop is shared, interval and fields only take effect on the
+// first call that creates it: a later call with different interval or
+// fields would otherwise be silently handed a loop polling on someone
+// else's cadence and field set, so Subscribe rejects it instead.
+func (c *Client) Subscribe(ctx context.Context, interval time.Duration, fields []string) (<-chan TorrentEvent, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("transmission: Subscribe interval must be positive, got %s", interval)
+	}
+
+	c.eventsMu.Lock()
+	if c.events == nil {
+		c.events = newEventLoop(c, interval, fields)
+	} else if c.events.interval != interval || !equalFields(c.events.fields, fields) {
+		c.eventsMu.Unlock()
+		return nil, fmt.Errorf("transmission: Subscribe called with interval=%s fields=%v, but the shared event loop is already running with interval=%s fields=%v", interval, fields, c.events.interval, c.events.fields)
+	}
+	loop := c.events
+	c.eventsMu.Unlock()
+
+	return loop.subscribe(ctx), nil
+}
+
+// equalFields reports whether a and b name the same fields in the same
+// order, so Subscribe can detect a second caller asking for a different
+// field set than the one the shared eventLoop was created with.
+func equalFields(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// eventLoop polls on an interval and fans out typed events to every live
+// subscriber. It is reference-counted via the subscriber count: the
+// underlying poll goroutine starts with the first subscriber and stops
+// once the last one unsubscribes, so closing one subscription never tears
+// down the others.
+type eventLoop struct {
+	client   *Client
+	interval time.Duration
+	fields   []string
+
+	mu     sync.Mutex
+	subs   map[chan TorrentEvent]struct{}
+	cancel context.CancelFunc
+	last   TorrentMap
+}
+
+func newEventLoop(c *Client, interval time.Duration, fields []string) *eventLoop {
+	return &eventLoop{
+		client:   c,
+		interval: interval,
+		fields:   fields,
+		subs:     make(map[chan TorrentEvent]struct{}),
+	}
+}
+
+func (e *eventLoop) subscribe(ctx context.Context) <-chan TorrentEvent {
+	ch := make(chan TorrentEvent, 16)
+
+	e.mu.Lock()
+	e.subs[ch] = struct{}{}
+	if e.cancel == nil {
+		loopCtx, cancel := context.WithCancel(context.Background())
+		e.cancel = cancel
+		go e.run(loopCtx)
+	}
+	e.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		e.unsubscribe(ch)
+	}()
+
+	return ch
+}
+
+func (e *eventLoop) unsubscribe(ch chan TorrentEvent) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, ok := e.subs[ch]; !ok {
+		return
+	}
+	delete(e.subs, ch)
+	close(ch)
+
+	if len(e.subs) == 0 && e.cancel != nil {
+		e.cancel()
+		e.cancel = nil
+	}
+}
+
+func (e *eventLoop) broadcast(ev TorrentEvent) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for ch := range e.subs {
+		select {
+		case ch <- ev:
+		default:
+			// Subscriber isn't keeping up; drop rather than block the loop.
+		}
+	}
+}
+
+func (e *eventLoop) run(ctx context.Context) {
+	backoff := e.interval
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			snapshot, err := e.poll()
+			if err != nil {
+				e.broadcast(TorrentEvent{Type: EventError, Err: err})
+				backoff *= 2
+				if backoff > time.Minute {
+					backoff = time.Minute
+				}
+				ticker.Reset(backoff)
+				continue
+			}
+
+			backoff = e.interval
+			ticker.Reset(backoff)
+
+			e.mu.Lock()
+			last := e.last
+			e.last = snapshot
+			e.mu.Unlock()
+
+			e.diff(last, snapshot)
+		}
+	}
+}
+
+func (e *eventLoop) poll() (TorrentMap, error) {
+	torrents, err := e.client.GetTorrents(e.fields)
+	if err != nil {
+		return nil, err
+	}
+
+	tm := make(TorrentMap, len(torrents))
+	for _, t := range torrents {
+		tm[t.HashString] = t
+	}
+	return tm, nil
+}
+
+func (e *eventLoop) diff(last, snapshot TorrentMap) {
+	for hash, t := range snapshot {
+		old, seen := last[hash]
+		if !seen {
+			e.broadcast(TorrentEvent{Type: TorrentAdded, Torrent: t})
+			continue
+		}
+
+		if old.Status != t.Status {
+			e.broadcast(TorrentEvent{Type: StateChanged, Torrent: t, From: old.Status, To: t.Status})
+		}
+		if !old.IsFinished && t.IsFinished {
+			e.broadcast(TorrentEvent{Type: Completed, Torrent: t})
+		}
+		if t.ErrorString != "" && t.ErrorString != old.ErrorString {
+			e.broadcast(TorrentEvent{Type: ErrorSet, Torrent: t, ErrorString: t.ErrorString})
+		}
+		if isTrackerError(t.Error) != isTrackerError(old.Error) {
+			e.broadcast(TorrentEvent{Type: TrackerStatusChanged, Torrent: t})
+		}
+
+		e.broadcast(TorrentEvent{
+			Type:        ProgressTick,
+			Torrent:     t,
+			PercentDone: t.PercentDone,
+			RateDown:    t.RateDownload,
+			RateUp:      t.RateUpload,
+		})
+	}
+
+	for hash, t := range last {
+		if _, stillThere := snapshot[hash]; !stillThere {
+			e.broadcast(TorrentEvent{Type: TorrentRemoved, Torrent: t})
+		}
+	}
+}
+
+// isTrackerError reports whether a Transmission torrent "error" code (1:
+// tracker warning, 2: tracker error) indicates a tracker-side problem, as
+// opposed to 0 (none) or 3 (local error).
+func isTrackerError(code int) bool {
+	return code == 1 || code == 2
+}
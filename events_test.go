@@ -0,0 +1,181 @@
+package transmission
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSubscribeRejectsNonPositiveInterval(t *testing.T) {
+	c := &Client{}
+
+	for _, interval := range []time.Duration{0, -time.Second} {
+		if _, err := c.Subscribe(context.Background(), interval, nil); err == nil {
+			t.Errorf("Subscribe(interval=%s): want error, got nil", interval)
+		}
+	}
+}
+
+func TestSubscribeRejectsMismatchedParams(t *testing.T) {
+	c := &Client{}
+	ctx := context.Background()
+
+	if _, err := c.Subscribe(ctx, time.Second, []string{"status"}); err != nil {
+		t.Fatalf("first Subscribe: unexpected error %v", err)
+	}
+
+	if _, err := c.Subscribe(ctx, 2*time.Second, []string{"status"}); err == nil {
+		t.Error("Subscribe with a different interval: want error, got nil")
+	}
+	if _, err := c.Subscribe(ctx, time.Second, []string{"name"}); err == nil {
+		t.Error("Subscribe with different fields: want error, got nil")
+	}
+	if _, err := c.Subscribe(ctx, time.Second, []string{"status"}); err != nil {
+		t.Errorf("Subscribe with matching params: unexpected error %v", err)
+	}
+}
+
+// collectEvents runs diff and drains whatever the subscriber channel
+// received without blocking.
+func collectEvents(t *testing.T, last, snapshot TorrentMap) []TorrentEvent {
+	t.Helper()
+
+	e := newEventLoop(nil, time.Second, nil)
+	ch := make(chan TorrentEvent, 16)
+	e.subs[ch] = struct{}{}
+
+	e.diff(last, snapshot)
+
+	var got []TorrentEvent
+	for {
+		select {
+		case ev := <-ch:
+			got = append(got, ev)
+		default:
+			return got
+		}
+	}
+}
+
+func eventTypes(events []TorrentEvent) []TorrentEventType {
+	types := make([]TorrentEventType, len(events))
+	for i, ev := range events {
+		types[i] = ev.Type
+	}
+	return types
+}
+
+func hasEventType(events []TorrentEvent, want TorrentEventType) bool {
+	for _, ev := range events {
+		if ev.Type == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestEventLoopDiffAdded(t *testing.T) {
+	snapshot := TorrentMap{"h1": {HashString: "h1"}}
+	events := collectEvents(t, TorrentMap{}, snapshot)
+
+	if !hasEventType(events, TorrentAdded) {
+		t.Errorf("want TorrentAdded, got %v", eventTypes(events))
+	}
+	if hasEventType(events, ProgressTick) {
+		// A brand new torrent is reported via TorrentAdded only, not also
+		// diffed against a non-existent previous state.
+		t.Errorf("did not expect ProgressTick for a newly added torrent, got %v", eventTypes(events))
+	}
+}
+
+func TestEventLoopDiffRemoved(t *testing.T) {
+	last := TorrentMap{"h1": {HashString: "h1"}}
+	events := collectEvents(t, last, TorrentMap{})
+
+	if !hasEventType(events, TorrentRemoved) {
+		t.Errorf("want TorrentRemoved, got %v", eventTypes(events))
+	}
+}
+
+func TestEventLoopDiffStateChanged(t *testing.T) {
+	last := TorrentMap{"h1": {HashString: "h1", Status: StatusDownload}}
+	snapshot := TorrentMap{"h1": {HashString: "h1", Status: StatusSeed}}
+	events := collectEvents(t, last, snapshot)
+
+	if !hasEventType(events, StateChanged) {
+		t.Fatalf("want StateChanged, got %v", eventTypes(events))
+	}
+	for _, ev := range events {
+		if ev.Type != StateChanged {
+			continue
+		}
+		if ev.From != StatusDownload || ev.To != StatusSeed {
+			t.Errorf("got From=%v To=%v, want From=%v To=%v", ev.From, ev.To, StatusDownload, StatusSeed)
+		}
+	}
+}
+
+func TestEventLoopDiffCompleted(t *testing.T) {
+	last := TorrentMap{"h1": {HashString: "h1", IsFinished: false}}
+	snapshot := TorrentMap{"h1": {HashString: "h1", IsFinished: true}}
+	events := collectEvents(t, last, snapshot)
+
+	if !hasEventType(events, Completed) {
+		t.Errorf("want Completed, got %v", eventTypes(events))
+	}
+}
+
+func TestEventLoopDiffErrorSet(t *testing.T) {
+	last := TorrentMap{"h1": {HashString: "h1", ErrorString: ""}}
+	snapshot := TorrentMap{"h1": {HashString: "h1", ErrorString: "disk full"}}
+	events := collectEvents(t, last, snapshot)
+
+	var found bool
+	for _, ev := range events {
+		if ev.Type == ErrorSet {
+			found = true
+			if ev.ErrorString != "disk full" {
+				t.Errorf("got ErrorString %q, want %q", ev.ErrorString, "disk full")
+			}
+		}
+	}
+	if !found {
+		t.Errorf("want ErrorSet, got %v", eventTypes(events))
+	}
+}
+
+func TestEventLoopDiffTrackerStatusChanged(t *testing.T) {
+	last := TorrentMap{"h1": {HashString: "h1", Error: 0}}
+	snapshot := TorrentMap{"h1": {HashString: "h1", Error: 2}} // tracker error
+	events := collectEvents(t, last, snapshot)
+
+	if !hasEventType(events, TrackerStatusChanged) {
+		t.Errorf("want TrackerStatusChanged, got %v", eventTypes(events))
+	}
+}
+
+func TestEventLoopDiffLocalErrorIsNotTrackerStatus(t *testing.T) {
+	last := TorrentMap{"h1": {HashString: "h1", Error: 0}}
+	snapshot := TorrentMap{"h1": {HashString: "h1", Error: 3}} // local error, not tracker
+	events := collectEvents(t, last, snapshot)
+
+	if hasEventType(events, TrackerStatusChanged) {
+		t.Errorf("did not want TrackerStatusChanged for a local error, got %v", eventTypes(events))
+	}
+}
+
+func TestEventLoopDiffProgressTickOnUnchangedTorrent(t *testing.T) {
+	last := TorrentMap{"h1": {HashString: "h1", PercentDone: 0.1}}
+	snapshot := TorrentMap{"h1": {HashString: "h1", PercentDone: 0.2}}
+	events := collectEvents(t, last, snapshot)
+
+	var found bool
+	for _, ev := range events {
+		if ev.Type == ProgressTick && ev.PercentDone == 0.2 {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("want ProgressTick with PercentDone=0.2, got %v", events)
+	}
+}
@@ -0,0 +1,59 @@
+package transmission
+
+import "fmt"
+
+// GroupConfig params for Client.SetGroup, mapping to the "group-set" RPC
+// method's arguments (besides the group's name, which is sent alongside
+// it). Only the fields set here are sent; zero values are omitted.
+type GroupConfig struct {
+	HonorsSessionLimits *bool `json:"honorsSessionLimits,omitempty"`
+
+	SpeedLimitDown        int   `json:"speed-limit-down,omitempty"`
+	SpeedLimitDownEnabled *bool `json:"speed-limit-down-enabled,omitempty"`
+	SpeedLimitUp          int   `json:"speed-limit-up,omitempty"`
+	SpeedLimitUpEnabled   *bool `json:"speed-limit-up-enabled,omitempty"`
+}
+
+// Group is a bandwidth group as returned by Client.GetGroups.
+type Group struct {
+	Name string `json:"name"`
+	GroupConfig
+}
+
+// SetGroup creates or updates a bandwidth group via the "group-set" RPC
+// method.
+func (c *Client) SetGroup(name string, cfg GroupConfig) error {
+	type request struct {
+		GroupConfig
+		Name string `json:"name"`
+	}
+
+	tReq := &Request{
+		Arguments: request{GroupConfig: cfg, Name: name},
+		Method:    "group-set",
+	}
+	r := &Response{}
+	return c.request(tReq, r)
+}
+
+// GetGroups fetches bandwidth groups via the "group-get" RPC method. An
+// empty names fetches every group.
+func (c *Client) GetGroups(names []string) ([]*Group, error) {
+	type arg struct {
+		Group []string `json:"group,omitempty"`
+	}
+	type groups struct {
+		Group []*Group `json:"group"`
+	}
+
+	tReq := &Request{
+		Arguments: arg{Group: names},
+		Method:    "group-get",
+	}
+	r := &Response{Arguments: &groups{}}
+	if err := c.request(tReq, r); err != nil {
+		return nil, fmt.Errorf("failed to fetch groups: %w", err)
+	}
+
+	return r.Arguments.(*groups).Group, nil
+}
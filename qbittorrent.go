@@ -0,0 +1,631 @@
+package transmission
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrNotSupported is returned by QBittorrentClient methods that have no
+// equivalent in the qBittorrent Web API.
+var ErrNotSupported = errors.New("operation not supported by this backend")
+
+// qbState is the subset of qBittorrent's torrent "state" values we care
+// about when normalizing to our own Status enum.
+type qbState string
+
+const (
+	qbStateDownloading        qbState = "downloading"
+	qbStateMetaDL             qbState = "metaDL"
+	qbStateStalledDL          qbState = "stalledDL"
+	qbStateQueuedDL           qbState = "queuedDL"
+	qbStateForcedDL           qbState = "forcedDL"
+	qbStateUploading          qbState = "uploading"
+	qbStateStalledUP          qbState = "stalledUP"
+	qbStateQueuedUP           qbState = "queuedUP"
+	qbStateForcedUP           qbState = "forcedUP"
+	qbStatePausedDL           qbState = "pausedDL"
+	qbStatePausedUP           qbState = "pausedUP"
+	qbStateCheckingDL         qbState = "checkingDL"
+	qbStateCheckingUP         qbState = "checkingUP"
+	qbStateCheckingResumeData qbState = "checkingResumeData"
+	qbStateError              qbState = "error"
+	qbStateMissingFiles       qbState = "missingFiles"
+)
+
+// statusFromQBittorrent maps a qBittorrent torrent state to the Status enum
+// used for Transmission torrents, so callers can treat *Torrent uniformly
+// regardless of which backend produced it.
+func statusFromQBittorrent(state qbState) Status {
+	switch state {
+	case qbStateDownloading, qbStateForcedDL, qbStateMetaDL:
+		return StatusDownload
+	case qbStateStalledDL, qbStateQueuedDL:
+		return StatusDownloadWait
+	case qbStateUploading, qbStateForcedUP:
+		return StatusSeed
+	case qbStateStalledUP, qbStateQueuedUP:
+		return StatusSeedWait
+	case qbStateCheckingDL, qbStateCheckingUP, qbStateCheckingResumeData:
+		return StatusCheck
+	case qbStatePausedDL, qbStatePausedUP, qbStateError, qbStateMissingFiles:
+		return StatusStopped
+	default:
+		return StatusStopped
+	}
+}
+
+// errorFromQBittorrent maps a qBittorrent torrent state that indicates a
+// problem to the (code, message) pair used for Torrent.Error/ErrorString,
+// so a qBittorrent torrent in an error state isn't indistinguishable from
+// one that's merely paused. Both map to Transmission's TR_STAT_LOCAL_ERROR
+// (3): unlike Transmission's own "error" field, qBittorrent's state enum
+// doesn't separate tracker-side problems from local ones, so there's no
+// reliable way to populate TrackerStatusChanged from this alone.
+func errorFromQBittorrent(state qbState) (code int, message string) {
+	switch state {
+	case qbStateMissingFiles:
+		return 3, "missing files"
+	case qbStateError:
+		return 3, "torrent error"
+	default:
+		return 0, ""
+	}
+}
+
+// QBittorrentConfig used to configure QBittorrentClient
+type QBittorrentConfig struct {
+	// Address base URL of the qBittorrent Web UI, e.g. http://localhost:8080
+	Address  string
+	User     string
+	Password string
+	// HTTPClient specify your own if you need default: http.Client with a
+	// cookie jar, so the SID cookie returned by /api/v2/auth/login is kept
+	// for every subsequent request.
+	HTTPClient *http.Client
+}
+
+// QBittorrentClient speaks the qBittorrent v2 Web API and implements
+// TorrentBackend so it can be used anywhere a TorrentBackend is accepted,
+// via NewQBittorrent instead of New. Methods of Client outside that
+// interface (SetTorrents, SetGroup, GetGroups, Subscribe) have no
+// QBittorrentClient counterpart.
+type QBittorrentClient struct {
+	Context context.Context
+	*QBittorrentConfig
+
+	mu       sync.Mutex
+	idByHash map[string]int
+	hashByID map[int]string
+	nextID   int
+}
+
+var _ TorrentBackend = (*QBittorrentClient)(nil)
+
+// NewQBittorrent creates a new qBittorrent-backed client and logs in.
+func NewQBittorrent(conf QBittorrentConfig) (*QBittorrentClient, error) {
+	if conf.HTTPClient == nil {
+		jar, err := cookiejar.New(nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create cookie jar: %w", err)
+		}
+		conf.HTTPClient = &http.Client{Jar: jar}
+	}
+
+	c := &QBittorrentClient{
+		Context:           context.Background(),
+		QBittorrentConfig: &conf,
+		idByHash:          make(map[string]int),
+		hashByID:          make(map[int]string),
+	}
+
+	if err := c.login(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (c *QBittorrentClient) login() error {
+	form := url.Values{
+		"username": {c.User},
+		"password": {c.Password},
+	}
+
+	req, err := http.NewRequestWithContext(c.Context, "POST", c.Address+"/api/v2/auth/login", strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute HTTP request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK || string(body) != "Ok." {
+		return fmt.Errorf("qbittorrent: login failed: %s", string(body))
+	}
+
+	// The SID cookie is now set on the jar backing c.HTTPClient, so it is
+	// sent automatically with every following request.
+	return nil
+}
+
+func (c *QBittorrentClient) get(path string, query url.Values) ([]byte, error) {
+	u := c.Address + path
+	if query != nil {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(c.Context, "GET", u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	return c.do(req)
+}
+
+func (c *QBittorrentClient) postForm(path string, form url.Values) ([]byte, error) {
+	req, err := http.NewRequestWithContext(c.Context, "POST", c.Address+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return c.do(req)
+}
+
+func (c *QBittorrentClient) do(req *http.Request) ([]byte, error) {
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute HTTP request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("unexpected HTTP status: %d: %s", resp.StatusCode, string(body))
+	}
+
+	return body, nil
+}
+
+// idForHash returns the stable int ID assigned to a qBittorrent infohash,
+// allocating a new one on first sight, so the rest of this package can keep
+// treating torrents as having an int ID like Transmission does.
+func (c *QBittorrentClient) idForHash(hash string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if id, ok := c.idByHash[hash]; ok {
+		return id
+	}
+
+	c.nextID++
+	id := c.nextID
+	c.idByHash[hash] = id
+	c.hashByID[id] = hash
+	return id
+}
+
+func (c *QBittorrentClient) hashForID(id int) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	hash, ok := c.hashByID[id]
+	return hash, ok
+}
+
+type qbTorrentInfo struct {
+	Hash     string  `json:"hash"`
+	Name     string  `json:"name"`
+	State    qbState `json:"state"`
+	Progress float64 `json:"progress"`
+	Dlspeed  int     `json:"dlspeed"`
+	Upspeed  int     `json:"upspeed"`
+	Eta      int     `json:"eta"`
+	SavePath string  `json:"save_path"`
+	Size     int64   `json:"size"`
+}
+
+// GetTorrents return list of torrent. fields is accepted for interface
+// compatibility with Client.GetTorrents but is otherwise ignored: the
+// qBittorrent API has no equivalent field-selection mechanism.
+func (c *QBittorrentClient) GetTorrents(fields []string) ([]*Torrent, error) {
+	body, err := c.get("/api/v2/torrents/info", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch torrents: %w", err)
+	}
+
+	var infos []qbTorrentInfo
+	if err := json.Unmarshal(body, &infos); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	torrents := make([]*Torrent, len(infos))
+	for i, info := range infos {
+		errCode, errString := errorFromQBittorrent(info.State)
+		torrents[i] = &Torrent{
+			ID:           c.idForHash(info.Hash),
+			HashString:   info.Hash,
+			Name:         info.Name,
+			Status:       statusFromQBittorrent(info.State),
+			PercentDone:  info.Progress,
+			RateDownload: info.Dlspeed,
+			RateUpload:   info.Upspeed,
+			Eta:          info.Eta,
+			DownloadDir:  info.SavePath,
+			SizeWhenDone: info.Size,
+			Error:        errCode,
+			ErrorString:  errString,
+		}
+	}
+
+	return torrents, nil
+}
+
+// GetTorrentMap returns a map of torrents indexed by torrent hash.
+func (c *QBittorrentClient) GetTorrentMap() (TorrentMap, error) {
+	torrents, err := c.GetTorrents(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	tm := make(TorrentMap)
+	for _, t := range torrents {
+		tm[t.HashString] = t
+	}
+	return tm, nil
+}
+
+// AddTorrent add torrent from filename or metadata, see AddTorrentArg for
+// arguments. The .torrent content is uploaded to "torrents/add" as
+// multipart/form-data, as required by the qBittorrent API. When Filename is
+// a plain http(s):// URL rather than a magnet URI, the new torrent is
+// identified by diffing the listing before and after the add instead of by
+// infohash; if that diff is ambiguous (e.g. a concurrent Add on the same
+// server landed in between), AddTorrent returns an error even though the
+// torrent was in fact added.
+func (c *QBittorrentClient) AddTorrent(args AddTorrentArg) (*Torrent, error) {
+	if args.Group != "" {
+		return nil, fmt.Errorf("qbittorrent: Group is not supported: %w", ErrNotSupported)
+	}
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	if args.DownloadDir != "" {
+		w.WriteField("savepath", args.DownloadDir)
+	}
+	if args.Paused {
+		w.WriteField("paused", "true")
+	}
+
+	metainfo := args.Metainfo
+	if len(args.Webseeds) > 0 {
+		if metainfo == "" {
+			return nil, fmt.Errorf("webseeds require Metainfo, Filename-only adds can't carry a url-list")
+		}
+		var err error
+		metainfo, err = injectWebseeds(metainfo, args.Webseeds)
+		if err != nil {
+			return nil, fmt.Errorf("failed to inject webseeds: %w", err)
+		}
+	}
+
+	switch {
+	case metainfo != "":
+		part, err := w.CreateFormFile("torrents", "upload.torrent")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create multipart file: %w", err)
+		}
+		decoded, err := base64.StdEncoding.DecodeString(metainfo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode metainfo: %w", err)
+		}
+		if _, err := part.Write(decoded); err != nil {
+			return nil, fmt.Errorf("failed to write torrent content: %w", err)
+		}
+	case args.Filename != "":
+		w.WriteField("urls", args.Filename)
+	default:
+		return nil, errors.New("qbittorrent: either Filename or Metainfo must be set")
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close multipart writer: %w", err)
+	}
+
+	// A plain Filename URL (as opposed to a magnet or Metainfo) has no
+	// infohash we can compute locally, so snapshot the current listing now
+	// and identify the new torrent by diffing against it after the add,
+	// rather than failing the call outright despite the add having
+	// succeeded server-side.
+	var before map[string]struct{}
+	if _, known := hashForAdd(args, metainfo); !known {
+		snapshot, err := c.GetTorrents(nil)
+		if err != nil {
+			return nil, err
+		}
+		before = make(map[string]struct{}, len(snapshot))
+		for _, t := range snapshot {
+			before[strings.ToLower(t.HashString)] = struct{}{}
+		}
+	}
+
+	req, err := http.NewRequestWithContext(c.Context, "POST", c.Address+"/api/v2/torrents/add", &buf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+
+	body, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(string(body)) != "Ok." {
+		return nil, fmt.Errorf("qbittorrent: failed to add torrent: %s", string(body))
+	}
+
+	// The add endpoint doesn't echo back the new torrent, unlike
+	// Transmission's torrent-add, so we fetch it from the listing and pick
+	// it out by infohash. We need the hash computed from what we sent
+	// rather than, say, the last element of the list: torrents/info has no
+	// ordering guarantee, so against a server with existing torrents (or
+	// under a concurrent Add) that would silently return the wrong entry.
+	hash, known := hashForAdd(args, metainfo)
+	if known {
+		torrents, err := c.GetTorrents(nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range torrents {
+			if strings.EqualFold(t.HashString, hash) {
+				return t, nil
+			}
+		}
+		return nil, fmt.Errorf("qbittorrent: added torrent %s not found in listing", hash)
+	}
+
+	torrents, err := c.GetTorrents(nil)
+	if err != nil {
+		return nil, err
+	}
+	added := diffNewTorrent(before, torrents)
+	if added == nil {
+		return nil, fmt.Errorf("qbittorrent: added torrent from %q not found in listing", args.Filename)
+	}
+	return added, nil
+}
+
+// diffNewTorrent returns the single torrent in after that wasn't present in
+// before, identified by hash, or nil if there's zero or more than one such
+// torrent: the latter can happen against a server with a concurrent Add in
+// flight, in which case we'd rather report "not found" than guess wrong.
+func diffNewTorrent(before map[string]struct{}, after []*Torrent) *Torrent {
+	var added *Torrent
+	for _, t := range after {
+		if _, existed := before[strings.ToLower(t.HashString)]; existed {
+			continue
+		}
+		if added != nil {
+			return nil
+		}
+		added = t
+	}
+	return added
+}
+
+// hashForAdd returns the infohash qBittorrent will assign the torrent being
+// added, computed locally so the result of AddTorrent can be looked up in
+// torrents/info without relying on list ordering: the SHA1 of the bencoded
+// info sub-dict for a Metainfo add, or the btih parsed out of a magnet URI
+// for a magnet Filename add. The second return value is false if the hash
+// can't be determined locally, e.g. Filename is a plain HTTP URL to a
+// .torrent file.
+func hashForAdd(args AddTorrentArg, metainfo string) (string, bool) {
+	if metainfo != "" {
+		return metainfoHash(metainfo)
+	}
+	if args.Filename != "" {
+		return magnetHash(args.Filename)
+	}
+	return "", false
+}
+
+func metainfoHash(metainfo string) (string, bool) {
+	raw, err := base64.StdEncoding.DecodeString(metainfo)
+	if err != nil {
+		return "", false
+	}
+
+	v, err := bencodeDecode(bufio.NewReader(bytes.NewReader(raw)))
+	if err != nil {
+		return "", false
+	}
+	dict, ok := v.(map[string]any)
+	if !ok {
+		return "", false
+	}
+	info, ok := dict["info"]
+	if !ok {
+		return "", false
+	}
+
+	var buf bytes.Buffer
+	if err := bencodeEncode(&buf, info); err != nil {
+		return "", false
+	}
+
+	sum := sha1.Sum(buf.Bytes())
+	return hex.EncodeToString(sum[:]), true
+}
+
+func magnetHash(uri string) (string, bool) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "magnet" {
+		return "", false
+	}
+
+	const prefix = "urn:btih:"
+	for _, xt := range u.Query()["xt"] {
+		if !strings.HasPrefix(xt, prefix) {
+			continue
+		}
+		hash := xt[len(prefix):]
+		if len(hash) == 40 {
+			// Hex-encoded SHA1, the only form torrents/info reports.
+			return strings.ToLower(hash), true
+		}
+		// Base32-encoded infohashes aren't handled: qBittorrent normalizes
+		// them to hex internally, so a string comparison against them here
+		// would never match anyway.
+	}
+	return "", false
+}
+
+// RemoveTorrents remove torrents
+func (c *QBittorrentClient) RemoveTorrents(torrents []*Torrent, removeData bool) error {
+	hashes := make([]string, len(torrents))
+	for i, t := range torrents {
+		hashes[i] = t.HashString
+	}
+
+	form := url.Values{
+		"hashes":      {strings.Join(hashes, "|")},
+		"deleteFiles": {strconv.FormatBool(removeData)},
+	}
+	_, err := c.postForm("/api/v2/torrents/delete", form)
+	return err
+}
+
+// QueueMoveTop moves torrents to top of the queue
+func (c *QBittorrentClient) QueueMoveTop(torrents []*Torrent) error {
+	return c.queueAction("/api/v2/torrents/topPrio", torrents)
+}
+
+// QueueMoveUp moves torrents up in the queue
+func (c *QBittorrentClient) QueueMoveUp(torrents []*Torrent) error {
+	return c.queueAction("/api/v2/torrents/increasePrio", torrents)
+}
+
+// QueueMoveDown moves torrents down in the queue
+func (c *QBittorrentClient) QueueMoveDown(torrents []*Torrent) error {
+	return c.queueAction("/api/v2/torrents/decreasePrio", torrents)
+}
+
+// QueueMoveBottom moves torrents to bottom of the queue
+func (c *QBittorrentClient) QueueMoveBottom(torrents []*Torrent) error {
+	return c.queueAction("/api/v2/torrents/bottomPrio", torrents)
+}
+
+func (c *QBittorrentClient) queueAction(path string, torrents []*Torrent) error {
+	hashes := make([]string, len(torrents))
+	for i, t := range torrents {
+		hashes[i] = t.HashString
+	}
+	form := url.Values{"hashes": {strings.Join(hashes, "|")}}
+	_, err := c.postForm(path, form)
+	return err
+}
+
+// FreeSpace reports the qBittorrent server's free space, read from
+// "sync/maindata"'s server_state.free_space_on_disk. path has no effect: the
+// qBittorrent Web API has no per-directory free-space query, so this always
+// reports free space for the default save path, regardless of what path
+// names.
+func (c *QBittorrentClient) FreeSpace(path string) (int, error) {
+	body, err := c.get("/api/v2/sync/maindata", nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch free space: %w", err)
+	}
+	var data struct {
+		ServerState struct {
+			FreeSpaceOnDisk int64 `json:"free_space_on_disk"`
+		} `json:"server_state"`
+	}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return int(data.ServerState.FreeSpaceOnDisk), nil
+}
+
+// PortTest is not exposed by the qBittorrent Web API.
+func (c *QBittorrentClient) PortTest() (bool, error) {
+	return false, ErrNotSupported
+}
+
+// BlocklistUpdate is not exposed by the qBittorrent Web API.
+func (c *QBittorrentClient) BlocklistUpdate() (int, error) {
+	return 0, ErrNotSupported
+}
+
+// GetSession fetches qBittorrent's application preferences and maps the
+// fields we understand onto our own Session type.
+func (c *QBittorrentClient) GetSession() (*Session, error) {
+	body, err := c.get("/api/v2/app/preferences", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch session: %w", err)
+	}
+	var prefs struct {
+		AltDlLimit       int  `json:"alt_dl_limit"`
+		AltUpLimit       int  `json:"alt_up_limit"`
+		SchedulerEnabled bool `json:"scheduler_enabled"`
+	}
+	if err := json.Unmarshal(body, &prefs); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return &Session{
+		AltSpeedDown:        prefs.AltDlLimit,
+		AltSpeedUp:          prefs.AltUpLimit,
+		AltSpeedTimeEnabled: prefs.SchedulerEnabled,
+	}, nil
+}
+
+// SetSession applies session arguments via qBittorrent's
+// "app/setPreferences" endpoint. Only AltSpeedDown, AltSpeedUp and
+// AltSpeedTimeEnabled are sent; AltSpeedEnabled and AltSpeedTimeBegin/End/Day
+// have no effect on this backend.
+func (c *QBittorrentClient) SetSession(args SessionSetArg) error {
+	prefs := map[string]any{}
+	if args.AltSpeedDown != 0 {
+		prefs["alt_dl_limit"] = args.AltSpeedDown
+	}
+	if args.AltSpeedUp != 0 {
+		prefs["alt_up_limit"] = args.AltSpeedUp
+	}
+	if args.AltSpeedTimeEnabled != nil {
+		prefs["scheduler_enabled"] = *args.AltSpeedTimeEnabled
+	}
+
+	encoded, err := json.Marshal(prefs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal preferences: %w", err)
+	}
+
+	form := url.Values{"json": {string(encoded)}}
+	_, err = c.postForm("/api/v2/app/setPreferences", form)
+	return err
+}
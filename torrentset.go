@@ -0,0 +1,71 @@
+package transmission
+
+// TrackerReplacement pairs a tracker's id with its replacement announce URL,
+// for TorrentSetArg.TrackerReplace.
+type TrackerReplacement struct {
+	ID       int
+	Announce string
+}
+
+// TorrentSetArg params for Client.SetTorrents, see the "torrent-set" section
+// of the Transmission RPC spec for the full list of accepted fields. Only
+// the fields set here are sent; zero values are omitted.
+type TorrentSetArg struct {
+	TrackerAdd    []string `json:"trackerAdd,omitempty"`
+	TrackerRemove []int    `json:"trackerRemove,omitempty"`
+
+	// TrackerReplace is flattened to the [id, announce, id, announce, ...]
+	// form the RPC spec expects when marshaled by SetTorrents; it is not
+	// marshaled directly off this field, see the json:"-" tag below.
+	TrackerReplace []TrackerReplacement `json:"-"`
+
+	SeedRatioLimit      float64 `json:"seedRatioLimit,omitempty"`
+	SeedRatioMode       int     `json:"seedRatioMode,omitempty"`
+	HonorsSessionLimits *bool   `json:"honorsSessionLimits,omitempty"`
+
+	DownloadLimit   int   `json:"downloadLimit,omitempty"`
+	DownloadLimited *bool `json:"downloadLimited,omitempty"`
+	UploadLimit     int   `json:"uploadLimit,omitempty"`
+	UploadLimited   *bool `json:"uploadLimited,omitempty"`
+
+	BandwidthPriority int `json:"bandwidthPriority,omitempty"`
+
+	FilesWanted   []int `json:"files-wanted,omitempty"`
+	FilesUnwanted []int `json:"files-unwanted,omitempty"`
+
+	PriorityHigh   []int `json:"priority-high,omitempty"`
+	PriorityLow    []int `json:"priority-low,omitempty"`
+	PriorityNormal []int `json:"priority-normal,omitempty"`
+
+	// Group assigns the torrents to a bandwidth group, see Client.SetGroup.
+	Group string `json:"group,omitempty"`
+}
+
+// SetTorrents updates the given torrents via the "torrent-set" RPC method.
+func (c *Client) SetTorrents(torrents []*Torrent, args TorrentSetArg) error {
+	ids := make([]int, len(torrents))
+	for i := range torrents {
+		ids[i] = torrents[i].ID
+	}
+
+	type request struct {
+		TorrentSetArg
+		Ids            []int `json:"ids"`
+		TrackerReplace []any `json:"trackerReplace,omitempty"`
+	}
+
+	var trackerReplace []any
+	if len(args.TrackerReplace) > 0 {
+		trackerReplace = make([]any, 0, len(args.TrackerReplace)*2)
+		for _, tr := range args.TrackerReplace {
+			trackerReplace = append(trackerReplace, tr.ID, tr.Announce)
+		}
+	}
+
+	tReq := &Request{
+		Arguments: request{TorrentSetArg: args, Ids: ids, TrackerReplace: trackerReplace},
+		Method:    "torrent-set",
+	}
+	r := &Response{}
+	return c.request(tReq, r)
+}
@@ -0,0 +1,56 @@
+package transmission
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+)
+
+// injectWebseeds decodes a base64-encoded .torrent metainfo, merges urls
+// into its top-level "url-list" (BEP 19 HTTP/FTP seeding), and re-encodes
+// the result back to base64 for use as AddTorrentArg.Metainfo.
+func injectWebseeds(metainfo string, urls []string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(metainfo)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode metainfo: %w", err)
+	}
+
+	v, err := bencodeDecode(bufio.NewReader(bytes.NewReader(raw)))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse metainfo: %w", err)
+	}
+
+	dict, ok := v.(map[string]any)
+	if !ok {
+		return "", fmt.Errorf("metainfo is not a bencoded dict")
+	}
+
+	// BEP19 allows url-list to be either a list of strings or, when there is
+	// only one, a bare string; bencodeDecode represents that bare string as
+	// []byte, not []any, so it needs wrapping or it would be silently
+	// dropped below.
+	var existing []any
+	switch v := dict["url-list"].(type) {
+	case []any:
+		existing = v
+	case []byte:
+		existing = []any{v}
+	case nil:
+		existing = nil
+	default:
+		return "", fmt.Errorf("metainfo url-list has unexpected type %T", v)
+	}
+
+	for _, u := range urls {
+		existing = append(existing, u)
+	}
+	dict["url-list"] = existing
+
+	var buf bytes.Buffer
+	if err := bencodeEncode(&buf, dict); err != nil {
+		return "", fmt.Errorf("failed to encode metainfo: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
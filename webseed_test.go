@@ -0,0 +1,89 @@
+package transmission
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"testing"
+)
+
+func encodeMetainfo(t *testing.T, dict map[string]any) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := bencodeEncode(&buf, dict); err != nil {
+		t.Fatalf("encode metainfo: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func decodeURLList(t *testing.T, metainfo string) []string {
+	t.Helper()
+	raw, err := base64.StdEncoding.DecodeString(metainfo)
+	if err != nil {
+		t.Fatalf("decode metainfo: %v", err)
+	}
+	v, err := bencodeDecode(bufio.NewReader(bytes.NewReader(raw)))
+	if err != nil {
+		t.Fatalf("parse metainfo: %v", err)
+	}
+	urls, _ := v.(map[string]any)["url-list"].([]any)
+	out := make([]string, len(urls))
+	for i, u := range urls {
+		out[i] = string(u.([]byte))
+	}
+	return out
+}
+
+func TestInjectWebseedsNoExisting(t *testing.T) {
+	metainfo := encodeMetainfo(t, map[string]any{
+		"info": map[string]any{"name": []byte("x")},
+	})
+
+	out, err := injectWebseeds(metainfo, []string{"http://b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := decodeURLList(t, out)
+	want := []string{"http://b"}
+	if len(got) != len(want) || got[0] != want[0] {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestInjectWebseedsExistingList(t *testing.T) {
+	metainfo := encodeMetainfo(t, map[string]any{
+		"info":     map[string]any{"name": []byte("x")},
+		"url-list": []any{[]byte("http://a")},
+	})
+
+	out, err := injectWebseeds(metainfo, []string{"http://b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := decodeURLList(t, out)
+	if len(got) != 2 || got[0] != "http://a" || got[1] != "http://b" {
+		t.Errorf("got %v, want [http://a http://b]", got)
+	}
+}
+
+// TestInjectWebseedsExistingString covers BEP19's single-URL form, where
+// url-list is a bare bencoded string rather than a one-element list; it
+// must be preserved, not silently dropped.
+func TestInjectWebseedsExistingString(t *testing.T) {
+	metainfo := encodeMetainfo(t, map[string]any{
+		"info":     map[string]any{"name": []byte("x")},
+		"url-list": []byte("http://a"),
+	})
+
+	out, err := injectWebseeds(metainfo, []string{"http://b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := decodeURLList(t, out)
+	if len(got) != 2 || got[0] != "http://a" || got[1] != "http://b" {
+		t.Errorf("existing webseed dropped: got %v, want [http://a http://b]", got)
+	}
+}